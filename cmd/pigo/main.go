@@ -33,6 +33,16 @@ type detectionResult struct {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		runServe(os.Args[2:])
+		return
+	}
+	runDetect()
+}
+
+// runDetect implements the default "pigo -in ... -out ..." command-line
+// face detection flow.
+func runDetect() {
 	var (
 		// Flags
 		source       = flag.String("in", "", "Source image")
@@ -46,6 +56,11 @@ func main() {
 		iouThreshold = flag.Float64("iou", 0.2, "Intersection over union (IoU) threshold")
 		circleMarker = flag.Bool("circle", false, "Use circle as detection marker")
 		outputAsJSON = flag.Bool("json", false, "Output face box coordinates into a json file")
+		puplocFile   = flag.String("puploc", "", "Pupil localization cascade file")
+		flplocDir    = flag.String("flploc", "", "Directory containing facial landmark cascade files")
+		perturb      = flag.Int("perturb", 63, "Number of perturbations used to average the localized landmark points")
+		autoOrient   = flag.Bool("autorotate", false, "Auto-rotate the source image according to its Exif orientation tag")
+		autoSize     = flag.Bool("autosize", false, "Derive the maximum face size from the source image dimensions instead of -max")
 	)
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, fmt.Sprintf(banner, Version))
@@ -53,8 +68,8 @@ func main() {
 	}
 	flag.Parse()
 
-	if len(*source) == 0 || len(*destination) == 0 || len(*cascadeFile) == 0 {
-		log.Fatal("Usage: pigo -in input.jpg -out out.png -cf data/facefinder")
+	if len(*source) == 0 || len(*destination) == 0 {
+		log.Fatal("Usage: pigo -in input.jpg -out out.png [-cf data/facefinder]")
 	}
 
 	fileTypes := []string{".jpg", ".jpeg", ".png"}
@@ -74,7 +89,32 @@ func main() {
 	start := time.Now()
 
 	fd := pigo.NewFaceDetector(*destination, *cascadeFile, *minSize, *maxSize, *shiftFactor, *scaleFactor, *iouThreshold, *angle)
-	faces, err := fd.DetectFaces(*source)
+	fd.AutoOrient = *autoOrient
+	fd.AutoSize = *autoSize
+
+	src, err := fd.LoadSource(*source)
+	if err != nil {
+		log.Fatalf("Error reading the source image: %v", err)
+	}
+
+	if len(*puplocFile) > 0 {
+		plc, err := loadPuplocCascade(*puplocFile)
+		if err != nil {
+			log.Fatalf("Error reading the pupil localization cascade: %v", err)
+		}
+
+		var flpcs map[string][]*pigo.FlpCascade
+		if len(*flplocDir) > 0 {
+			flpcs, err = pigo.ReadCascadeDir(os.DirFS(filepath.Dir(*flplocDir)), filepath.Base(*flplocDir))
+			if err != nil {
+				log.Fatalf("Error reading the landmark cascade directory: %v", err)
+			}
+		}
+
+		fd.SetLandmarkCascades(plc, flpcs, *perturb)
+	}
+
+	faces, err := fd.DetectFaces(src)
 	if err != nil {
 		log.Fatalf("Detection error: %v", err)
 	}
@@ -86,7 +126,7 @@ func main() {
 	}
 
 	resp := detectionResult{
-		coords: rects,
+		coords: fd.TranslateRects(rects),
 	}
 
 	out, err := json.Marshal(resp.coords)
@@ -127,6 +167,15 @@ func (s *spinner) stop() {
 	s.stopChan <- struct{}{}
 }
 
+// loadPuplocCascade reads and unpacks the pupil localization cascade file.
+func loadPuplocCascade(path string) (*pigo.PuplocCascade, error) {
+	packet, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return pigo.NewPuplocCascade().UnpackCascade(packet)
+}
+
 // inSlice check if a slice contains the string value.
 func inSlice(ext string, types []string) bool {
 	for _, t := range types {
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"image"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/NohaSayedA/pigo/core"
+)
+
+// server holds the default detection parameters served by "pigo serve" and
+// the shared, concurrency-safe Detector backing /detect.
+type server struct {
+	defaultCascade string
+	defaults       pigo.DetectorParams
+	detector       *pigo.Detector
+}
+
+// faceResponse is the JSON representation of a single detected face.
+type faceResponse struct {
+	Row   int     `json:"row"`
+	Col   int     `json:"col"`
+	Scale int     `json:"scale"`
+	Q     float32 `json:"q"`
+}
+
+// detectResponse is the JSON body returned by POST /detect.
+type detectResponse struct {
+	Faces []faceResponse `json:"faces"`
+}
+
+// runServe starts the "pigo serve" HTTP detection server. It depends on
+// the concurrent Detector (see core/detector.go) so a single unpacked
+// classifier can safely serve many requests at once.
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "HTTP listen address")
+	cascadeFile := fs.String("cf", "", "Default cascade binary file (falls back to the embedded facefinder)")
+	minSize := fs.Int("min", 20, "Default minimum size of face")
+	maxSize := fs.Int("max", 1000, "Default maximum size of face")
+	shiftFactor := fs.Float64("shift", 0.1, "Default shift factor")
+	scaleFactor := fs.Float64("scale", 1.1, "Default scale factor")
+	iouThreshold := fs.Float64("iou", 0.2, "Default IoU threshold")
+	angle := fs.Float64("angle", 0.0, "Default angle, 0.0 is 0 radians and 1.0 is 2*pi radians")
+	fs.Parse(args)
+
+	srv := &server{
+		defaultCascade: *cascadeFile,
+		defaults: pigo.DetectorParams{
+			MinSize:      *minSize,
+			MaxSize:      *maxSize,
+			ShiftFactor:  *shiftFactor,
+			ScaleFactor:  *scaleFactor,
+			IouThreshold: *iouThreshold,
+			Angle:        *angle,
+		},
+	}
+
+	// Building the default detector once at startup both validates the
+	// cascade eagerly and warms the embedded-cascade sync.Once. It's then
+	// reused across requests instead of being rebuilt on every /detect call.
+	det, err := pigo.NewDetector(*cascadeFile, srv.defaults)
+	if err != nil {
+		log.Fatalf("Error initializing the detector: %v", err)
+	}
+	srv.detector = det
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", srv.handleHealthz)
+	mux.HandleFunc("/detect", srv.handleDetect)
+
+	log.Printf("pigo serve listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+func (s *server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+func (s *server) handleDetect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	src, err := decodeRequestImage(r)
+	if err != nil {
+		http.Error(w, "Error decoding the request image: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	params := s.defaults
+	q := r.URL.Query()
+	params.MinSize = queryInt(q, "minSize", params.MinSize)
+	params.MaxSize = queryInt(q, "maxSize", params.MaxSize)
+	params.ShiftFactor = queryFloat(q, "shift", params.ShiftFactor)
+	params.ScaleFactor = queryFloat(q, "scale", params.ScaleFactor)
+	params.IouThreshold = queryFloat(q, "iou", params.IouThreshold)
+	params.Angle = queryFloat(q, "angle", params.Angle)
+
+	cascadeFile := s.defaultCascade
+	overridden := params != s.defaults
+	if cf := q.Get("cascade"); len(cf) > 0 {
+		cascadeFile = cf
+		overridden = true
+	}
+
+	// The common case reuses the Detector built once at startup. Only a
+	// request that overrides the cascade file or the default parameters
+	// pays for unpacking a cascade and allocating fresh pools.
+	det := s.detector
+	if overridden {
+		var err error
+		det, err = pigo.NewDetector(cascadeFile, params)
+		if err != nil {
+			http.Error(w, "Error initializing the detector: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	faces, err := det.Detect(src)
+	if err != nil {
+		http.Error(w, "Detection error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := detectResponse{Faces: make([]faceResponse, len(faces))}
+	for i, face := range faces {
+		resp.Faces[i] = faceResponse{Row: face.Row, Col: face.Col, Scale: face.Scale, Q: face.Q}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// decodeRequestImage decodes the image carried by a /detect request, which
+// may be a raw image body or a multipart/form-data upload under the
+// "image" field.
+func decodeRequestImage(r *http.Request) (image.Image, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err == nil && mediaType == "multipart/form-data" {
+		file, _, err := r.FormFile("image")
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		src, _, err := image.Decode(file)
+		return src, err
+	}
+
+	src, _, err := image.Decode(r.Body)
+	return src, err
+}
+
+func queryInt(q map[string][]string, key string, def int) int {
+	v := firstQueryValue(q, key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+func queryFloat(q map[string][]string, key string, def float64) float64 {
+	v := firstQueryValue(q, key)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return def
+	}
+	return f
+}
+
+func firstQueryValue(q map[string][]string, key string) string {
+	values, ok := q[key]
+	if !ok || len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
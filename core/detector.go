@@ -0,0 +1,260 @@
+package pigo
+
+import (
+	"image"
+	"image/color"
+	"io/ioutil"
+	"runtime"
+	"sync"
+)
+
+// DetectorParams holds the cascade search parameters used by a Detector.
+// It mirrors the per-call settings faceDetector takes, but is supplied
+// once at construction time since a Detector is shared across callers.
+type DetectorParams struct {
+	MinSize      int
+	MaxSize      int
+	ShiftFactor  float64
+	ScaleFactor  float64
+	IouThreshold float64
+	Angle        float64
+}
+
+// Detector is a reusable, concurrency-safe face detector. It unpacks its
+// cascade once at construction time instead of on every call, and reuses
+// pixel buffers across detections via sync.Pool, making it suitable for
+// server and batch-processing use cases that faceDetector wasn't designed
+// for (faceDetector re-reads and re-unpacks the cascade file per call and
+// draws onto a single package-level *gg.Context, so concurrent callers
+// would corrupt each other's output).
+type Detector struct {
+	classifier *Pigo
+	params     DetectorParams
+
+	puploc  *PuplocCascade
+	flpcs   map[string][]*FlpCascade
+	perturb int
+
+	nrgbaPool sync.Pool
+	grayPool  sync.Pool
+}
+
+// SetLandmarkCascades configures the detector to additionally localize the
+// pupils and, when lpcs is non-empty, the mouth landmark point for every
+// detected face. perturb controls how many times the search window is
+// perturbed and averaged for a stable estimate.
+func (d *Detector) SetLandmarkCascades(puploc *PuplocCascade, lpcs map[string][]*FlpCascade, perturb int) {
+	d.puploc = puploc
+	d.flpcs = lpcs
+	d.perturb = perturb
+}
+
+// NewDetector unpacks cascadeFile once and returns a Detector ready to
+// serve concurrent Detect/BatchDetect calls. An empty cascadeFile falls
+// back to the facefinder cascade embedded into the binary.
+func NewDetector(cascadeFile string, params DetectorParams) (*Detector, error) {
+	var (
+		classifier *Pigo
+		err        error
+	)
+
+	if len(cascadeFile) == 0 {
+		classifier, err = DefaultClassifier()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		packet, rerr := ioutil.ReadFile(cascadeFile)
+		if rerr != nil {
+			return nil, rerr
+		}
+		classifier, err = NewPigo().Unpack(packet)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Detector{
+		classifier: classifier,
+		params:     params,
+		nrgbaPool:  sync.Pool{New: func() interface{} { return new(image.NRGBA) }},
+		grayPool:   sync.Pool{New: func() interface{} { return new([]uint8) }},
+	}, nil
+}
+
+// Detect runs face detection over src. It is safe to call Detect
+// concurrently from multiple goroutines on the same Detector.
+func (d *Detector) Detect(src image.Image) ([]Detection, error) {
+	faces, _, release, err := d.detect(src)
+	if release != nil {
+		defer release()
+	}
+	return faces, err
+}
+
+// DetectWithLandmarks behaves like Detect, additionally localizing pupils
+// and (when configured via SetLandmarkCascades) the mouth point for every
+// detected face.
+func (d *Detector) DetectWithLandmarks(src image.Image) ([]FaceLandmarks, error) {
+	faces, img, release, err := d.detect(src)
+	if release != nil {
+		defer release()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]FaceLandmarks, len(faces))
+	for i, face := range faces {
+		results[i] = FaceLandmarks{
+			Detection: face,
+			Landmarks: detectLandmarks(d.puploc, d.flpcs, d.perturb, d.params.Angle, face, img),
+		}
+	}
+	return results, nil
+}
+
+// detect runs the cascade over src and returns the detections together
+// with the ImageParams they were computed against. The returned release
+// func must be called once the caller is done using the ImageParams'
+// pixel buffer; it returns the buffer to the Detector's pool.
+func (d *Detector) detect(src image.Image) ([]Detection, ImageParams, func(), error) {
+	nrgbaPtr := d.nrgbaPool.Get().(*image.NRGBA)
+	nrgba := nrgbaInto(src, nrgbaPtr)
+
+	cols, rows := src.Bounds().Max.X, src.Bounds().Max.Y
+
+	pixelsPtr := d.grayPool.Get().(*[]uint8)
+	pixels := grayscaleInto(nrgba, *pixelsPtr)
+	*pixelsPtr = pixels
+
+	// nrgba is only read by grayscaleInto above; it's safe to return to
+	// the pool now, but the gray buffer stays checked out until release
+	// is called, since callers keep reading it via the returned ImageParams.
+	d.nrgbaPool.Put(nrgba)
+	release := func() { d.grayPool.Put(pixelsPtr) }
+
+	img := ImageParams{
+		Pixels: pixels,
+		Rows:   rows,
+		Cols:   cols,
+		Dim:    cols,
+	}
+
+	cParams := CascadeParams{
+		MinSize:     d.params.MinSize,
+		MaxSize:     d.params.MaxSize,
+		ShiftFactor: d.params.ShiftFactor,
+		ScaleFactor: d.params.ScaleFactor,
+		ImageParams: img,
+	}
+
+	faces := d.classifier.RunCascade(cParams, d.params.Angle)
+	faces = d.classifier.ClusterDetections(faces, d.params.IouThreshold)
+
+	return faces, img, release, nil
+}
+
+// nrgbaInto converts src to *image.NRGBA, reusing dst's pixel buffer when
+// it's large enough instead of allocating a new image every call.
+func nrgbaInto(src image.Image, dst *image.NRGBA) *image.NRGBA {
+	bounds := src.Bounds().Sub(src.Bounds().Min)
+	n := bounds.Dx() * bounds.Dy() * 4
+
+	if cap(dst.Pix) < n {
+		dst.Pix = make([]uint8, n)
+	}
+	dst.Pix = dst.Pix[:n]
+	dst.Stride = bounds.Dx() * 4
+	dst.Rect = bounds
+
+	minX, minY := src.Bounds().Min.X, src.Bounds().Min.Y
+	for y := 0; y < bounds.Dy(); y++ {
+		i := dst.PixOffset(0, y)
+		for x := 0; x < bounds.Dx(); x++ {
+			c := color.NRGBAModel.Convert(src.At(minX+x, minY+y)).(color.NRGBA)
+			dst.Pix[i+0] = c.R
+			dst.Pix[i+1] = c.G
+			dst.Pix[i+2] = c.B
+			dst.Pix[i+3] = c.A
+			i += 4
+		}
+	}
+	return dst
+}
+
+// grayscaleInto converts img to grayscale, reusing dst's backing array
+// when it's large enough instead of allocating a new one every call.
+func grayscaleInto(img *image.NRGBA, dst []uint8) []uint8 {
+	bounds := img.Bounds()
+	n := bounds.Dx() * bounds.Dy()
+
+	if cap(dst) < n {
+		dst = make([]uint8, n)
+	}
+	dst = dst[:n]
+
+	i := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			dst[i] = uint8((0.2989*float64(r>>8) + 0.5870*float64(g>>8) + 0.1140*float64(b>>8)))
+			i++
+		}
+	}
+	return dst
+}
+
+// Result is the outcome of detecting faces in a single image, returned
+// over the channel BatchDetect produces.
+type Result struct {
+	Path  string
+	Faces []Detection
+	Err   error
+}
+
+// BatchDetect fans out face detection over paths using a pool of workers
+// goroutines (runtime.NumCPU() when workers <= 0), all sharing the same
+// already-unpacked classifier. Results are delivered over the returned
+// channel in completion order and the channel is closed once every path
+// has been processed.
+func (d *Detector) BatchDetect(paths []string, workers int) (<-chan Result, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	jobs := make(chan string)
+	results := make(chan Result)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				src, err := GetImage(path)
+				if err != nil {
+					results <- Result{Path: path, Err: err}
+					continue
+				}
+
+				faces, err := d.Detect(src)
+				results <- Result{Path: path, Faces: faces, Err: err}
+			}
+		}()
+	}
+
+	go func() {
+		for _, path := range paths {
+			jobs <- path
+		}
+		close(jobs)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results, nil
+}
@@ -0,0 +1,57 @@
+package pigo
+
+import (
+	"image"
+	"image/color"
+	"sync"
+	"testing"
+)
+
+// TestNrgbaGrayscalePoolReuseConcurrent exercises the same
+// Get -> nrgbaInto -> grayscaleInto -> Put sequence Detector.detect uses
+// its pools for, concurrently and with varying source images, so that
+// `go test -race` catches a buffer being returned to the pool (and
+// possibly reused by another goroutine) before it's done being read.
+func TestNrgbaGrayscalePoolReuseConcurrent(t *testing.T) {
+	nrgbaPool := sync.Pool{New: func() interface{} { return new(image.NRGBA) }}
+	grayPool := sync.Pool{New: func() interface{} { return new([]uint8) }}
+
+	const goroutines = 32
+	const itersPerGoroutine = 50
+
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for g := 0; g < goroutines; g++ {
+		shade := uint8(g % 256)
+		go func(shade uint8) {
+			defer wg.Done()
+			for i := 0; i < itersPerGoroutine; i++ {
+				uniform := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+				for y := 0; y < 8; y++ {
+					for x := 0; x < 8; x++ {
+						uniform.Set(x, y, color.Gray{Y: shade})
+					}
+				}
+
+				nrgbaPtr := nrgbaPool.Get().(*image.NRGBA)
+				nrgba := nrgbaInto(uniform, nrgbaPtr)
+
+				pixelsPtr := grayPool.Get().(*[]uint8)
+				pixels := grayscaleInto(nrgba, *pixelsPtr)
+				*pixelsPtr = pixels
+
+				nrgbaPool.Put(nrgba)
+
+				want := uint8(0.2989*float64(shade) + 0.5870*float64(shade) + 0.1140*float64(shade))
+				for _, p := range pixels {
+					if p != want {
+						t.Errorf("grayscale pixel = %d, want %d (buffer corrupted by a concurrent reuse)", p, want)
+					}
+				}
+
+				grayPool.Put(pixelsPtr)
+			}
+		}(shade)
+	}
+	wg.Wait()
+}
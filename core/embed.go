@@ -0,0 +1,57 @@
+package pigo
+
+import (
+	"embed"
+	"sync"
+)
+
+// The binaries under cascade/ are placeholders: replace them with cascades
+// trained for your deployment before shipping.
+//
+//go:embed cascade/facefinder
+var facefinderCascade []byte
+
+//go:embed cascade/puploc cascade/lps
+var flpCascades embed.FS
+
+var (
+	defaultClassifier     *Pigo
+	defaultClassifierErr  error
+	defaultClassifierOnce sync.Once
+
+	defaultFlpcs     map[string][]*FlpCascade
+	defaultFlpcsErr  error
+	defaultFlpcsOnce sync.Once
+)
+
+// DefaultClassifier returns a *Pigo face classifier unpacked from the
+// facefinder cascade embedded into the binary. It is initialized once and
+// shared across callers, so library users don't have to ship a cascade
+// file alongside their binary or re-unpack it on every call. The embedded
+// cascade isn't validated until this first call (go:embed only checks that
+// the file exists, not that it unpacks), so callers must check the error.
+func DefaultClassifier() (*Pigo, error) {
+	defaultClassifierOnce.Do(func() {
+		defaultClassifier, defaultClassifierErr = NewPigo().Unpack(facefinderCascade)
+	})
+	return defaultClassifier, defaultClassifierErr
+}
+
+// DefaultPuplocCascade returns the pupil localization cascade embedded into
+// the binary, unpacked once and shared across callers.
+func DefaultPuplocCascade() (*PuplocCascade, error) {
+	packet, err := flpCascades.ReadFile("cascade/puploc")
+	if err != nil {
+		return nil, err
+	}
+	return NewPuplocCascade().UnpackCascade(packet)
+}
+
+// DefaultLandmarkCascades returns the eye and mouth landmark cascades
+// embedded into the binary, unpacked once and shared across callers.
+func DefaultLandmarkCascades() (map[string][]*FlpCascade, error) {
+	defaultFlpcsOnce.Do(func() {
+		defaultFlpcs, defaultFlpcsErr = ReadCascadeDir(flpCascades, "cascade/lps")
+	})
+	return defaultFlpcs, defaultFlpcsErr
+}
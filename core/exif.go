@@ -0,0 +1,103 @@
+package pigo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// errNoExifOrientation is returned when a JPEG carries no Exif orientation
+// tag, in which case callers should treat the image as already upright.
+var errNoExifOrientation = errors.New("pigo: no Exif orientation tag found")
+
+// readOrientation extracts the Exif orientation tag (1-8) from a JPEG file,
+// returning errNoExifOrientation when the file has no Exif APP1 segment or
+// no orientation tag. Portrait photos straight off a phone camera are
+// typically stored in landscape pixel order with this tag set to describe
+// how a viewer should rotate/flip them; without honoring it the cascade
+// scans the sideways pixel data and misses faces.
+func readOrientation(r io.Reader) (int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 0, errNoExifOrientation
+	}
+
+	for pos := 2; pos+4 <= len(data); {
+		if data[pos] != 0xFF {
+			return 0, errNoExifOrientation
+		}
+		marker := data[pos+1]
+		segLen := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+
+		// segLen is attacker/file-controlled: a truncated or malformed
+		// segment must fail closed instead of slicing out of bounds.
+		if segLen < 2 || pos+2+segLen > len(data) {
+			return 0, errNoExifOrientation
+		}
+
+		if marker == 0xE1 { // APP1
+			segment := data[pos+4 : pos+2+segLen]
+			if orientation, err := parseExifOrientation(segment); err == nil {
+				return orientation, nil
+			}
+			return 0, errNoExifOrientation
+		}
+
+		if marker == 0xDA { // Start of scan: no more metadata segments follow
+			break
+		}
+
+		pos += 2 + segLen
+	}
+
+	return 0, errNoExifOrientation
+}
+
+// parseExifOrientation reads the orientation tag out of a TIFF-encoded
+// Exif APP1 payload.
+func parseExifOrientation(segment []byte) (int, error) {
+	if !bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+		return 0, errNoExifOrientation
+	}
+	tiff := segment[6:]
+	if len(tiff) < 8 {
+		return 0, errNoExifOrientation
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, errNoExifOrientation
+	}
+
+	ifdOffset := order.Uint32(tiff[4:8])
+	if int(ifdOffset)+2 > len(tiff) {
+		return 0, errNoExifOrientation
+	}
+
+	numEntries := int(order.Uint16(tiff[ifdOffset : ifdOffset+2]))
+	entries := tiff[ifdOffset+2:]
+
+	const orientationTag = 0x0112
+	for i := 0; i < numEntries; i++ {
+		off := i * 12
+		if off+12 > len(entries) {
+			break
+		}
+		tag := order.Uint16(entries[off : off+2])
+		if tag == orientationTag {
+			return int(order.Uint16(entries[off+8 : off+10])), nil
+		}
+	}
+
+	return 0, errNoExifOrientation
+}
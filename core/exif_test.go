@@ -0,0 +1,64 @@
+package pigo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// buildJPEGWithOrientation assembles a minimal JPEG: SOI + an APP1 segment
+// carrying a one-entry TIFF IFD with the given Exif orientation tag.
+func buildJPEGWithOrientation(t *testing.T, orientation uint16) []byte {
+	t.Helper()
+
+	tiff := new(bytes.Buffer)
+	tiff.WriteString("II")
+	binary.Write(tiff, binary.LittleEndian, uint16(42))
+	binary.Write(tiff, binary.LittleEndian, uint32(8)) // IFD offset
+	binary.Write(tiff, binary.LittleEndian, uint16(1)) // one entry
+	binary.Write(tiff, binary.LittleEndian, uint16(0x0112))
+	binary.Write(tiff, binary.LittleEndian, uint16(3)) // type SHORT
+	binary.Write(tiff, binary.LittleEndian, uint32(1)) // count
+	binary.Write(tiff, binary.LittleEndian, orientation)
+	binary.Write(tiff, binary.LittleEndian, uint16(0)) // padding
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+
+	jpeg := new(bytes.Buffer)
+	jpeg.Write([]byte{0xFF, 0xD8, 0xFF, 0xE1})
+	binary.Write(jpeg, binary.BigEndian, uint16(len(payload)+2))
+	jpeg.Write(payload)
+	jpeg.Write([]byte{0xFF, 0xDA}) // start of scan
+
+	return jpeg.Bytes()
+}
+
+func TestReadOrientation(t *testing.T) {
+	data := buildJPEGWithOrientation(t, 6)
+
+	got, err := readOrientation(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("readOrientation returned an error: %v", err)
+	}
+	if got != 6 {
+		t.Fatalf("readOrientation = %d, want 6", got)
+	}
+}
+
+func TestReadOrientationTruncatedSegmentDoesNotPanic(t *testing.T) {
+	// SOI + an APP1 marker claiming a segment far longer than the data
+	// that actually follows.
+	data := []byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF, 0xFF}
+
+	_, err := readOrientation(bytes.NewReader(data))
+	if err != errNoExifOrientation {
+		t.Fatalf("got err=%v, want errNoExifOrientation", err)
+	}
+}
+
+func TestReadOrientationNotAJPEG(t *testing.T) {
+	_, err := readOrientation(bytes.NewReader([]byte("not a jpeg")))
+	if err != errNoExifOrientation {
+		t.Fatalf("got err=%v, want errNoExifOrientation", err)
+	}
+}
@@ -0,0 +1,38 @@
+package pigo
+
+// FlpCascade holds a facial landmark point cascade (eyebrow corners, mouth
+// corners, etc). It reuses the same binary tree format and search strategy
+// as the pupil localization cascade, so it wraps a PuplocCascade internally.
+type FlpCascade struct {
+	*PuplocCascade
+}
+
+// NewLpCascade wraps an already unpacked PuplocCascade so it can be used to
+// locate a facial landmark point (as opposed to a pupil) via GetLandmarkPoint.
+func NewLpCascade(plc *PuplocCascade) *FlpCascade {
+	return &FlpCascade{PuplocCascade: plc}
+}
+
+// UnpackCascade unpacks a facial landmark point cascade file. The binary
+// layout is identical to the pupil localization cascade.
+func (flp *FlpCascade) UnpackCascade(packet []byte) (*FlpCascade, error) {
+	plc, err := NewPuplocCascade().UnpackCascade(packet)
+	if err != nil {
+		return nil, err
+	}
+	flp.PuplocCascade = plc
+	return flp, nil
+}
+
+// GetLandmarkPoint runs the landmark cascade around the pupil position,
+// perturbing the search window perturbs times and averaging the result
+// to stabilize the localized point.
+func (flp *FlpCascade) GetLandmarkPoint(pupil Puploc, img ImageParams, perturbs int, angle float64) Puploc {
+	lp := Puploc{
+		Row:      pupil.Row,
+		Col:      pupil.Col,
+		Scale:    pupil.Scale,
+		Perturbs: perturbs,
+	}
+	return flp.RunDetector(lp, img, angle)
+}
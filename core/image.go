@@ -1,6 +1,7 @@
 package pigo
 
 import (
+	"fmt"
 	"github.com/fogleman/gg"
 	"image"
 	"image/color"
@@ -9,11 +10,34 @@ import (
 	"image/png"
 	_ "image/png"
 	"io/ioutil"
+	"log"
 	"math"
 	"os"
 	"path/filepath"
 )
 
+// autoSizeMargin is subtracted from the smaller image dimension to derive
+// the maximum face size when AutoSize is enabled, leaving a small border
+// so a face spanning nearly the whole image still clusters correctly.
+const autoSizeMargin = 10
+
+// ErrImageTooSmall is returned by DetectFaces when the source image is
+// smaller than the detector's minimum face size in either dimension.
+type ErrImageTooSmall struct {
+	Width, Height, MinSize int
+}
+
+func (e *ErrImageTooSmall) Error() string {
+	return fmt.Sprintf("pigo: image %dx%d is smaller than the minimum face size %d", e.Width, e.Height, e.MinSize)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 var dc *gg.Context
 
 // faceDetector struct contains Pigo face detector general settings.
@@ -26,6 +50,39 @@ type faceDetector struct {
 	shiftFactor  float64
 	scaleFactor  float64
 	iouThreshold float64
+	puploc       *PuplocCascade
+	flpcs        map[string][]*FlpCascade
+	perturb      int
+
+	// AutoOrient, when true, makes LoadSource honor the Exif orientation
+	// tag of JPEG inputs instead of scanning them in their stored
+	// (possibly sideways) pixel order.
+	AutoOrient bool
+	orientation int
+	origWidth   int
+	origHeight  int
+
+	// AutoSize, when true, makes DetectFaces ignore maxSize and derive it
+	// from the source image's dimensions instead.
+	AutoSize bool
+}
+
+// FaceLandmarks pairs a detected face with its facial landmark points.
+// Landmarks is nil when the detector wasn't configured with a puploc
+// cascade (see SetLandmarkCascades).
+type FaceLandmarks struct {
+	Detection
+	Landmarks *Landmarks
+}
+
+// SetLandmarkCascades configures the detector to additionally localize the
+// pupils and, when lpcs is non-empty, the eye and mouth landmark points for
+// every detected face. perturb controls how many times the search window is
+// perturbed and averaged for a stable estimate.
+func (fd *faceDetector) SetLandmarkCascades(puploc *PuplocCascade, lpcs map[string][]*FlpCascade, perturb int) {
+	fd.puploc = puploc
+	fd.flpcs = lpcs
+	fd.perturb = perturb
 }
 
 // GetImage retrieves and decodes the image file to *image.NRGBA type.
@@ -44,6 +101,49 @@ func GetImage(input string) (image.Image, error) {
 	return src, nil
 }
 
+// LoadSource reads and decodes the image at path. When fd.AutoOrient is
+// set, JPEGs are auto-rotated/flipped according to their Exif orientation
+// tag before being returned, and the orientation and original (as-stored)
+// dimensions are recorded so TranslateRects can later map detections back
+// into the original file's coordinate system.
+func (fd *faceDetector) LoadSource(path string) (image.Image, error) {
+	if !fd.AutoOrient {
+		return GetImage(path)
+	}
+
+	src, orientation, err := GetOrientedImage(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fd.orientation = orientation
+	b := src.Bounds()
+	if orientation >= 5 && orientation <= 8 {
+		// 90/270 degree rotations swap width and height relative to the
+		// as-stored file.
+		fd.origWidth, fd.origHeight = b.Dy(), b.Dx()
+	} else {
+		fd.origWidth, fd.origHeight = b.Dx(), b.Dy()
+	}
+
+	return src, nil
+}
+
+// TranslateRects maps rects, detected against the (possibly auto-oriented)
+// image handed to DetectFaces, back into the coordinate system of the
+// original as-stored file. It's a no-op when fd.AutoOrient wasn't set.
+func (fd *faceDetector) TranslateRects(rects []image.Rectangle) []image.Rectangle {
+	if !fd.AutoOrient || fd.orientation <= 1 {
+		return rects
+	}
+
+	translated := make([]image.Rectangle, len(rects))
+	for i, r := range rects {
+		translated[i] = TranslateRect(r, fd.orientation, fd.origWidth, fd.origHeight)
+	}
+	return translated
+}
+
 // newFaceDetector initialises the constructor function.
 func NewFaceDetector(destination, cf string, minSize, maxSize int, shf, scf, iou, angle float64) *faceDetector {
 	return &faceDetector{
@@ -59,17 +159,31 @@ func NewFaceDetector(destination, cf string, minSize, maxSize int, shf, scf, iou
 }
 
 // detectFaces run the detection algorithm over the provided source image.
-func (fd *faceDetector) DetectFaces(src image.Image) ([]Detection, error) {
+func (fd *faceDetector) DetectFaces(src image.Image) ([]FaceLandmarks, error) {
 	res := ImgToNRGBA(src)
 	pixels := RgbToGrayscale(res)
 	cols, rows := src.Bounds().Max.X, src.Bounds().Max.Y
 
+	maxSize := fd.maxSize
+	if fd.AutoSize {
+		if cols < fd.minSize || rows < fd.minSize {
+			return nil, &ErrImageTooSmall{Width: cols, Height: rows, MinSize: fd.minSize}
+		}
+		// The margin can still push maxSize below minSize for an image only
+		// slightly larger than the minimum face size; clamp it back up
+		// rather than silently scanning an empty, always-zero-result range.
+		if maxSize = minInt(cols, rows) - autoSizeMargin; maxSize < fd.minSize {
+			maxSize = fd.minSize
+		}
+	}
+	log.Printf("image size %dx%d, face size min %d, max %d", cols, rows, fd.minSize, maxSize)
+
 	dc = gg.NewContext(cols, rows)
 	dc.DrawImage(src, 0, 0)
 
 	cParams := CascadeParams{
 		MinSize:     fd.minSize,
-		MaxSize:     fd.maxSize,
+		MaxSize:     maxSize,
 		ShiftFactor: fd.shiftFactor,
 		ScaleFactor: fd.scaleFactor,
 		ImageParams: ImageParams{
@@ -80,17 +194,27 @@ func (fd *faceDetector) DetectFaces(src image.Image) ([]Detection, error) {
 		},
 	}
 
-	cascadeFile, err := ioutil.ReadFile(fd.cascadeFile)
-	if err != nil {
-		return nil, err
-	}
+	var classifier *Pigo
+	if len(fd.cascadeFile) == 0 {
+		// No cascade file was supplied: fall back to the facefinder cascade
+		// embedded into the binary so callers don't have to ship one.
+		var err error
+		classifier, err = DefaultClassifier()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		cascadeFile, err := ioutil.ReadFile(fd.cascadeFile)
+		if err != nil {
+			return nil, err
+		}
 
-	pigo := NewPigo()
-	// Unpack the binary file. This will return the number of cascade trees,
-	// the tree depth, the threshold and the prediction from tree's leaf nodes.
-	classifier, err := pigo.Unpack(cascadeFile)
-	if err != nil {
-		return nil, err
+		// Unpack the binary file. This will return the number of cascade trees,
+		// the tree depth, the threshold and the prediction from tree's leaf nodes.
+		classifier, err = NewPigo().Unpack(cascadeFile)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Run the classifier over the obtained leaf nodes and return the detection results.
@@ -100,11 +224,19 @@ func (fd *faceDetector) DetectFaces(src image.Image) ([]Detection, error) {
 	// Calculate the intersection over union (IoU) of two clusters.
 	faces = classifier.ClusterDetections(faces, fd.iouThreshold)
 
-	return faces, nil
+	results := make([]FaceLandmarks, len(faces))
+	for i, face := range faces {
+		results[i] = FaceLandmarks{
+			Detection: face,
+			Landmarks: detectLandmarks(fd.puploc, fd.flpcs, fd.perturb, fd.angle, face, cParams.ImageParams),
+		}
+	}
+
+	return results, nil
 }
 
 // drawFaces marks the detected faces with a circle in case isCircle is true, otherwise marks with a rectangle.
-func (fd *faceDetector) DrawFaces(faces []Detection, isCircle bool) ([]byte, []image.Rectangle, error) {
+func (fd *faceDetector) DrawFaces(faces []FaceLandmarks, isCircle bool) ([]byte, []image.Rectangle, error) {
 	var (
 		qThresh float32 = 5.0
 		rects   []image.Rectangle
@@ -137,6 +269,12 @@ func (fd *faceDetector) DrawFaces(faces []Detection, isCircle bool) ([]byte, []i
 			dc.SetLineWidth(2.0)
 			dc.SetStrokeStyle(gg.NewSolidPattern(color.RGBA{R: 255, G: 0, B: 0, A: 255}))
 			dc.Stroke()
+
+			if face.Landmarks != nil {
+				drawLandmarkMarker(face.Landmarks.LeftEye)
+				drawLandmarkMarker(face.Landmarks.RightEye)
+				drawLandmarkMarker(face.Landmarks.Mouth)
+			}
 		}
 	}
 
@@ -160,6 +298,13 @@ func (fd *faceDetector) DrawFaces(faces []Detection, isCircle bool) ([]byte, []i
 	return rf, rects, err
 }
 
+// drawLandmarkMarker marks a localized landmark point with a small green dot.
+func drawLandmarkMarker(p Puploc) {
+	dc.DrawPoint(float64(p.Col), float64(p.Row), 2.0)
+	dc.SetFillStyle(gg.NewSolidPattern(color.RGBA{R: 0, G: 255, B: 0, A: 255}))
+	dc.Fill()
+}
+
 // ImgToNRGBA converts any image type to *image.NRGBA with min-point at (0, 0).
 func ImgToNRGBA(img image.Image) *image.NRGBA {
 	srcBounds := img.Bounds()
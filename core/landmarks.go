@@ -0,0 +1,123 @@
+package pigo
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// Landmarks holds the facial landmark points detected around a face, in
+// the original image's coordinate system.
+type Landmarks struct {
+	LeftEye  Puploc
+	RightEye Puploc
+	Mouth    Puploc
+}
+
+// landmarkGroups maps a cascade file name prefix to the facial region it
+// locates. Eye cascades are tried against both eyes, mouth cascades only
+// against the mouth region.
+var landmarkGroups = map[string]string{
+	"lp46":  "eyes",
+	"lp44":  "eyes",
+	"lp42":  "eyes",
+	"lp38":  "eyes",
+	"lp312": "eyes",
+	"lp93":  "mouth",
+	"lp84":  "mouth",
+	"lp82":  "mouth",
+	"lp81":  "mouth",
+}
+
+// ReadCascadeDir loads every facial landmark cascade file found in fsys,
+// rooted at dir, into a map keyed by cascade name (e.g. "lp46"). fsys can be
+// an os.DirFS for a plain directory on disk or an embed.FS for cascades
+// embedded into the binary.
+func ReadCascadeDir(fsys fs.FS, dir string) (map[string][]*FlpCascade, error) {
+	flpcs := make(map[string][]*FlpCascade)
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		packet, err := fs.ReadFile(fsys, filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		flp, err := NewLpCascade(NewPuplocCascade()).UnpackCascade(packet)
+		if err != nil {
+			return nil, err
+		}
+
+		group, ok := landmarkGroups[baseName(name)]
+		if !ok {
+			group = baseName(name)
+		}
+		flpcs[group] = append(flpcs[group], flp)
+	}
+
+	return flpcs, nil
+}
+
+func baseName(name string) string {
+	name = filepath.Base(name)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// chainLandmark refines start by running it through cascades in order, each
+// one searching around the previous cascade's result, and reports whether
+// any cascade ran at all. Chaining several cascades of increasing precision
+// around the same point is what makes loading more than one of them useful.
+func chainLandmark(cascades []*FlpCascade, start Puploc, img ImageParams, perturb int, angle float64) (Puploc, bool) {
+	if len(cascades) == 0 {
+		return Puploc{}, false
+	}
+
+	point := start
+	for _, flp := range cascades {
+		point = flp.GetLandmarkPoint(point, img, perturb, angle)
+	}
+	return point, true
+}
+
+// detectLandmarks locates the pupils and, when landmark cascades are
+// supplied, refines the eye and mouth points for a single detected face.
+// puploc may be nil, in which case no landmarks are located and nil is
+// returned.
+func detectLandmarks(puploc *PuplocCascade, flpcs map[string][]*FlpCascade, perturb int, angle float64, face Detection, img ImageParams) *Landmarks {
+	if puploc == nil {
+		return nil
+	}
+
+	row, col, scale := face.Row, face.Col, float64(face.Scale)/2
+
+	leftEyePl := Puploc{Row: row - int(0.075*scale), Col: col - int(0.175*scale), Scale: scale, Perturbs: perturb}
+	rightEyePl := Puploc{Row: row - int(0.075*scale), Col: col + int(0.175*scale), Scale: scale, Perturbs: perturb}
+
+	landmarks := &Landmarks{
+		LeftEye:  puploc.RunDetector(leftEyePl, img, angle),
+		RightEye: puploc.RunDetector(rightEyePl, img, angle),
+	}
+
+	if refined, ok := chainLandmark(flpcs["eyes"], landmarks.LeftEye, img, perturb, angle); ok {
+		landmarks.LeftEye = refined
+	}
+	if refined, ok := chainLandmark(flpcs["eyes"], landmarks.RightEye, img, perturb, angle); ok {
+		landmarks.RightEye = refined
+	}
+
+	mouthPl := Puploc{Row: row + int(0.2*scale), Col: col, Scale: scale, Perturbs: perturb}
+	if refined, ok := chainLandmark(flpcs["mouth"], mouthPl, img, perturb, angle); ok {
+		landmarks.Mouth = refined
+	}
+
+	return landmarks
+}
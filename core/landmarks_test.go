@@ -0,0 +1,42 @@
+package pigo
+
+import "testing"
+
+func TestChainLandmarkNoCascades(t *testing.T) {
+	img := ImageParams{Pixels: make([]uint8, 100), Rows: 10, Cols: 10, Dim: 10}
+	start := Puploc{Row: 5, Col: 5, Scale: 4, Perturbs: 1}
+
+	if _, ok := chainLandmark(nil, start, img, 1, 0); ok {
+		t.Fatal("chainLandmark with no cascades should report ok=false")
+	}
+}
+
+func TestChainLandmarkRunsEachCascadeInOrder(t *testing.T) {
+	packet := buildPuplocPacket(t, 1.0, [4]int8{-1, 0, 1, 0}, [2][2]float32{{2, 0}, {-2, 0}})
+
+	first, err := NewLpCascade(NewPuplocCascade()).UnpackCascade(packet)
+	if err != nil {
+		t.Fatalf("unpacking first cascade: %v", err)
+	}
+	second, err := NewLpCascade(NewPuplocCascade()).UnpackCascade(packet)
+	if err != nil {
+		t.Fatalf("unpacking second cascade: %v", err)
+	}
+
+	img := ImageParams{Pixels: make([]uint8, 100), Rows: 10, Cols: 10, Dim: 10}
+	start := Puploc{Row: 5, Col: 5, Scale: 4, Perturbs: 1}
+
+	chained, ok := chainLandmark([]*FlpCascade{first, second}, start, img, 1, 0)
+	if !ok {
+		t.Fatal("chainLandmark with cascades should report ok=true")
+	}
+
+	// Running the same two cascades by hand, each starting from the
+	// previous one's output, must match chainLandmark's result exactly.
+	want := first.GetLandmarkPoint(start, img, 1, 0)
+	want = second.GetLandmarkPoint(want, img, 1, 0)
+
+	if chained != want {
+		t.Fatalf("chainLandmark = %+v, want %+v (cascades weren't chained)", chained, want)
+	}
+}
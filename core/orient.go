@@ -0,0 +1,95 @@
+package pigo
+
+import (
+	"image"
+	"io"
+	"os"
+
+	"github.com/disintegration/imaging"
+)
+
+// GetOrientedImage behaves like GetImage but additionally reads the Exif
+// orientation tag (JPEGs only, see readOrientation) and rotates/flips the
+// decoded image so it is displayed upright, the same way
+// disintegration/imaging's AutoOrientation option works. The orientation
+// tag is returned alongside the image so callers that need to report
+// coordinates in the original file's frame (see TranslateRect) can undo
+// the transform later.
+func GetOrientedImage(input string) (image.Image, int, error) {
+	file, err := os.Open(input)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	orientation, err := readOrientation(file)
+	if err != nil {
+		orientation = 1
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, 0, err
+	}
+
+	src, _, err := image.Decode(file)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return applyOrientation(src, orientation), orientation, nil
+}
+
+// applyOrientation rotates/flips img according to the Exif orientation
+// value (1-8, per the TIFF/Exif spec), returning img unchanged for 1 or
+// an unrecognized value.
+func applyOrientation(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return imaging.FlipH(img)
+	case 3:
+		return imaging.Rotate180(img)
+	case 4:
+		return imaging.FlipV(img)
+	case 5:
+		return imaging.Transpose(img)
+	case 6:
+		return imaging.Rotate270(img)
+	case 7:
+		return imaging.Transverse(img)
+	case 8:
+		return imaging.Rotate90(img)
+	default:
+		return img
+	}
+}
+
+// TranslateRect maps rect, detected against the auto-oriented image, back
+// into the coordinate system of the original as-stored file (origW x
+// origH), undoing the rotation/flip applied for the given Exif
+// orientation. Orientation 1 (or any unrecognized value) is a no-op.
+func TranslateRect(rect image.Rectangle, orientation, origW, origH int) image.Rectangle {
+	minX, minY := invertOrientedPoint(rect.Min.X, rect.Min.Y, orientation, origW, origH)
+	maxX, maxY := invertOrientedPoint(rect.Max.X, rect.Max.Y, orientation, origW, origH)
+	return image.Rect(minX, minY, maxX, maxY).Canon()
+}
+
+func invertOrientedPoint(x, y, orientation, origW, origH int) (int, int) {
+	switch orientation {
+	case 2:
+		return origW - x, y
+	case 3:
+		return origW - x, origH - y
+	case 4:
+		return x, origH - y
+	case 5:
+		return y, x
+	case 6:
+		return y, origH - x
+	case 7:
+		return origW - y, origH - x
+	case 8:
+		return origW - y, x
+	default:
+		return x, y
+	}
+}
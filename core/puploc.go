@@ -0,0 +1,197 @@
+package pigo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/rand"
+)
+
+// Puploc holds the detection window coordinates together with the
+// number of perturbations to apply around it when searching for the pupil.
+type Puploc struct {
+	Row      int
+	Col      int
+	Scale    float64
+	Perturbs int
+}
+
+// PuplocCascade holds the binary forest values used for pupil localization,
+// unpacked from a cascade file produced by the pigo trainer. The forest is
+// organized as a sequence of stages, each holding one or more regression
+// trees; a stage's trees vote on a row/col correction, and the search
+// window is then rescaled by that stage's scale multiplier before the next
+// stage runs.
+type PuplocCascade struct {
+	Depth         int
+	Stages        int
+	TreesPerStage int
+	Treshold      float64
+	StageScales   []float32
+	Tcodes        [][][]int8
+	Lutpreds      [][][][2]float32
+}
+
+// NewPuplocCascade instantiates a new, empty pupil localization cascade.
+// Call UnpackCascade to populate it from a binary cascade file.
+func NewPuplocCascade() *PuplocCascade {
+	return &PuplocCascade{}
+}
+
+// UnpackCascade unpacks the pupil localization cascade file and returns
+// the populated PuplocCascade so it can run pupil detection via RunDetector.
+//
+// The binary layout is:
+//
+//	uint32       depth            tree depth, shared by every tree
+//	uint32       stages           number of stages
+//	uint32       treesPerStage    trees per stage
+//	float32      threshold
+//	per stage:
+//	  float32    stageScale       multiplies the search window scale
+//	                              before the next stage runs
+//	  per tree:
+//	    int8[4 * (2^depth - 1)]   split codes
+//	    float32[2 * 2^depth]     leaf row/col predictions
+func (plc *PuplocCascade) UnpackCascade(packet []byte) (*PuplocCascade, error) {
+	buff := bytes.NewReader(packet)
+
+	var depth, stages, treesPerStage uint32
+	if err := binary.Read(buff, binary.LittleEndian, &depth); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(buff, binary.LittleEndian, &stages); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(buff, binary.LittleEndian, &treesPerStage); err != nil {
+		return nil, err
+	}
+
+	var treshold float32
+	if err := binary.Read(buff, binary.LittleEndian, &treshold); err != nil {
+		return nil, err
+	}
+
+	plc.Depth = int(depth)
+	plc.Stages = int(stages)
+	plc.TreesPerStage = int(treesPerStage)
+	plc.Treshold = float64(treshold)
+
+	nodes := int(math.Pow(2, float64(plc.Depth))) - 1
+	leaves := int(math.Pow(2, float64(plc.Depth)))
+
+	plc.StageScales = make([]float32, plc.Stages)
+	plc.Tcodes = make([][][]int8, plc.Stages)
+	plc.Lutpreds = make([][][][2]float32, plc.Stages)
+
+	for s := 0; s < plc.Stages; s++ {
+		if err := binary.Read(buff, binary.LittleEndian, &plc.StageScales[s]); err != nil {
+			return nil, err
+		}
+
+		plc.Tcodes[s] = make([][]int8, plc.TreesPerStage)
+		plc.Lutpreds[s] = make([][][2]float32, plc.TreesPerStage)
+
+		for t := 0; t < plc.TreesPerStage; t++ {
+			codes := make([]int8, 4*nodes)
+			if err := binary.Read(buff, binary.LittleEndian, &codes); err != nil {
+				return nil, err
+			}
+			plc.Tcodes[s][t] = codes
+
+			preds := make([][2]float32, leaves)
+			if err := binary.Read(buff, binary.LittleEndian, &preds); err != nil {
+				return nil, err
+			}
+			plc.Lutpreds[s][t] = preds
+		}
+	}
+
+	return plc, nil
+}
+
+// RunDetector runs the pupil localization cascade over the region of interest
+// defined by puploc, perturbing the search window plc.Perturbs times and
+// averaging the results to obtain a stable pupil estimate.
+func (plc *PuplocCascade) RunDetector(puploc Puploc, img ImageParams, angle float64) Puploc {
+	var (
+		sumRow, sumCol, sumScale float64
+		n                        float64
+	)
+
+	row, col, scale := puploc.Row, puploc.Col, puploc.Scale
+	nodes := int(math.Pow(2, float64(plc.Depth))) - 1
+
+	for i := 0; i < puploc.Perturbs; i++ {
+		r, c, s := row, col, scale
+
+		for st := 0; st < plc.Stages; st++ {
+			var predRow, predCol float64
+
+			for t := 0; t < plc.TreesPerStage; t++ {
+				idx := 0
+				codes := plc.Tcodes[st][t]
+
+				for d := 0; d < plc.Depth; d++ {
+					r1 := clampCoord(r+int(float64(codes[idx*4])*s), 0, img.Rows-1)
+					c1 := clampCoord(c+int(float64(codes[idx*4+1])*s), 0, img.Cols-1)
+					r2 := clampCoord(r+int(float64(codes[idx*4+2])*s), 0, img.Rows-1)
+					c2 := clampCoord(c+int(float64(codes[idx*4+3])*s), 0, img.Cols-1)
+
+					p1 := img.Pixels[r1*img.Dim+c1]
+					p2 := img.Pixels[r2*img.Dim+c2]
+
+					if p1 <= p2 {
+						idx = 2*idx + 1
+					} else {
+						idx = 2*idx + 2
+					}
+				}
+
+				leaf := idx - nodes
+				if leaf < 0 || leaf >= len(plc.Lutpreds[st][t]) {
+					continue
+				}
+				pred := plc.Lutpreds[st][t][leaf]
+				predRow += float64(pred[0])
+				predCol += float64(pred[1])
+			}
+
+			r += int(predRow * s)
+			c += int(predCol * s)
+			if st < len(plc.StageScales) {
+				s *= float64(plc.StageScales[st])
+			}
+		}
+
+		sumRow += float64(r)
+		sumCol += float64(c)
+		sumScale += s
+		n++
+
+		// Jitter the window slightly for the next perturbation round so the
+		// averaged result is less sensitive to the initial face detection box.
+		row = puploc.Row + rand.Intn(3) - 1
+		col = puploc.Col + rand.Intn(3) - 1
+	}
+
+	if n == 0 {
+		return Puploc{Row: row, Col: col, Scale: scale}
+	}
+
+	return Puploc{
+		Row:   int(sumRow / n),
+		Col:   int(sumCol / n),
+		Scale: sumScale / n,
+	}
+}
+
+func clampCoord(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
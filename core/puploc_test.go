@@ -0,0 +1,88 @@
+package pigo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// buildPuplocPacket assembles a minimal synthetic cascade binary matching
+// the layout documented on PuplocCascade.UnpackCascade: one stage holding
+// one depth-1 tree (a single split node, two leaves).
+func buildPuplocPacket(t *testing.T, stageScale float32, codes [4]int8, preds [2][2]float32) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	write := func(v interface{}) {
+		if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+			t.Fatalf("building test packet: %v", err)
+		}
+	}
+
+	write(uint32(1)) // depth
+	write(uint32(1)) // stages
+	write(uint32(1)) // treesPerStage
+	write(float32(0.0))
+	write(stageScale)
+	write(codes)
+	write(preds)
+
+	return buf.Bytes()
+}
+
+func TestPuplocCascadeUnpackCascade(t *testing.T) {
+	packet := buildPuplocPacket(t, 1.0, [4]int8{-1, 0, 1, 0}, [2][2]float32{{2, 0}, {-2, 0}})
+
+	plc, err := NewPuplocCascade().UnpackCascade(packet)
+	if err != nil {
+		t.Fatalf("UnpackCascade returned an error: %v", err)
+	}
+
+	if plc.Depth != 1 || plc.Stages != 1 || plc.TreesPerStage != 1 {
+		t.Fatalf("got Depth=%d Stages=%d TreesPerStage=%d, want 1/1/1", plc.Depth, plc.Stages, plc.TreesPerStage)
+	}
+	if len(plc.Tcodes) != 1 || len(plc.Tcodes[0]) != 1 || len(plc.Tcodes[0][0]) != 4 {
+		t.Fatalf("unexpected Tcodes shape: %+v", plc.Tcodes)
+	}
+	if len(plc.Lutpreds) != 1 || len(plc.Lutpreds[0]) != 1 || len(plc.Lutpreds[0][0]) != 2 {
+		t.Fatalf("unexpected Lutpreds shape: %+v", plc.Lutpreds)
+	}
+}
+
+func TestPuplocCascadeUnpackCascadeTruncated(t *testing.T) {
+	if _, err := NewPuplocCascade().UnpackCascade([]byte{0x01, 0x02}); err == nil {
+		t.Fatal("expected an error unpacking a truncated packet, got nil")
+	}
+}
+
+func TestPuplocCascadeRunDetector(t *testing.T) {
+	packet := buildPuplocPacket(t, 1.0, [4]int8{-1, 0, 1, 0}, [2][2]float32{{2, 0}, {-2, 0}})
+	plc, err := NewPuplocCascade().UnpackCascade(packet)
+	if err != nil {
+		t.Fatalf("UnpackCascade returned an error: %v", err)
+	}
+
+	const dim = 10
+	pixels := make([]uint8, dim*dim)
+	for i := range pixels {
+		pixels[i] = uint8(i % 256)
+	}
+	img := ImageParams{Pixels: pixels, Rows: dim, Cols: dim, Dim: dim}
+
+	result := plc.RunDetector(Puploc{Row: 5, Col: 5, Scale: 4, Perturbs: 3}, img, 0)
+
+	if result.Row < 0 || result.Row >= dim || result.Col < 0 || result.Col >= dim {
+		t.Fatalf("RunDetector returned an out-of-bounds point: %+v", result)
+	}
+}
+
+func TestPuplocCascadeRunDetectorNoPerturbs(t *testing.T) {
+	plc := NewPuplocCascade()
+	start := Puploc{Row: 3, Col: 4, Scale: 2, Perturbs: 0}
+	img := ImageParams{Pixels: make([]uint8, 100), Rows: 10, Cols: 10, Dim: 10}
+
+	got := plc.RunDetector(start, img, 0)
+	if got.Row != start.Row || got.Col != start.Col || got.Scale != start.Scale {
+		t.Fatalf("RunDetector with Perturbs=0 should return the input point unchanged, got %+v", got)
+	}
+}